@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes mounts the coordinator's node-management and work-claim
+// endpoints on router. Workers call these; a standalone node never does.
+func RegisterRoutes(router *mux.Router, store *Store) {
+	router.HandleFunc("/api/cluster/nodes", registerNodeHandler(store)).Methods(http.MethodPost)
+	router.HandleFunc("/api/cluster/nodes/{id}/heartbeat", heartbeatHandler(store)).Methods(http.MethodPost)
+	router.HandleFunc("/api/cluster/claim", claimWorkHandler(store)).Methods(http.MethodPost)
+	router.HandleFunc("/api/cluster/results", submitResultsHandler(store)).Methods(http.MethodPost)
+}
+
+type registerNodeRequest struct {
+	ID           string   `json:"id"`
+	Address      string   `json:"address"`
+	Capabilities []string `json:"capabilities"`
+}
+
+func registerNodeHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req registerNodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := store.RegisterNode(r.Context(), req.ID, req.Address, req.Capabilities); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func heartbeatHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if err := store.Heartbeat(r.Context(), id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type claimWorkRequest struct {
+	NodeID string `json:"node_id"`
+	Limit  int    `json:"limit"`
+}
+
+func claimWorkHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req claimWorkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Limit <= 0 {
+			req.Limit = 1
+		}
+
+		items, err := store.ClaimWork(r.Context(), req.NodeID, req.Limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	}
+}
+
+func submitResultsHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SubmitResultsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := store.MergeResults(r.Context(), req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,52 @@
+// Package cluster implements reconya's optional coordinator/worker mode:
+// for large networks, one node coordinates work and others claim CIDR
+// ranges to scan and report results back, instead of a single process
+// having to scan everything itself.
+package cluster
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role selects how this process participates in a cluster.
+type Role string
+
+const (
+	RoleStandalone  Role = "standalone"
+	RoleCoordinator Role = "coordinator"
+	RoleWorker      Role = "worker"
+)
+
+// ParseRole validates a --role flag value, defaulting to standalone so
+// today's single-node behavior is preserved unless clustering is opted into.
+func ParseRole(value string) (Role, error) {
+	switch Role(value) {
+	case "", RoleStandalone:
+		return RoleStandalone, nil
+	case RoleCoordinator:
+		return RoleCoordinator, nil
+	case RoleWorker:
+		return RoleWorker, nil
+	default:
+		return "", fmt.Errorf("unknown cluster role %q (want standalone, coordinator or worker)", value)
+	}
+}
+
+// Node is a worker registered with the coordinator.
+type Node struct {
+	ID           string
+	Address      string
+	LastSeen     time.Time
+	Capabilities []string
+}
+
+// HasCapability reports whether the node advertises cap (e.g. "can-run-nuclei").
+func (n Node) HasCapability(cap string) bool {
+	for _, c := range n.Capabilities {
+		if c == cap {
+			return true
+		}
+	}
+	return false
+}
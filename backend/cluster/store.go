@@ -0,0 +1,350 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reconya-ai/outputs"
+)
+
+// DefaultLeaseDuration is how long a claimed network is reserved for a
+// worker before it's considered abandoned and eligible to be reclaimed.
+const DefaultLeaseDuration = 10 * time.Minute
+
+// Pipeline is the subset of *pd.Pipeline's behavior MergeResults needs.
+// It's expressed as an interface here, rather than importing internal/pd
+// directly, so the cluster package doesn't take on that package's much
+// heavier set of scanning dependencies just to run the pipeline after a
+// merge.
+type Pipeline interface {
+	RunForDevice(ctx context.Context, db *sql.DB, deviceID, domain string, hosts []string) error
+}
+
+// Store persists cluster state - registered nodes and network work
+// assignment - in the same SQLite database used for scan results. Pipeline
+// and Dispatcher are both optional: when set, MergeResults runs Pipeline
+// against every merged device the same way a local device scan would, and
+// emits device/port events to Dispatcher the same way discovery.Merger and
+// the pd pipeline do.
+type Store struct {
+	db            *sql.DB
+	LeaseDuration time.Duration
+	Pipeline      Pipeline
+	Dispatcher    *outputs.Dispatcher
+}
+
+// NewStore returns a Store using DefaultLeaseDuration.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, LeaseDuration: DefaultLeaseDuration}
+}
+
+// RegisterNode inserts or refreshes the node record identified by id.
+func (s *Store) RegisterNode(ctx context.Context, id, address string, capabilities []string) error {
+	caps, err := json.Marshal(capabilities)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capabilities: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO nodes (id, address, capabilities, last_seen)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			address = excluded.address,
+			capabilities = excluded.capabilities,
+			last_seen = excluded.last_seen`,
+		id, address, string(caps), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register node %s: %w", id, err)
+	}
+	return nil
+}
+
+// Heartbeat refreshes last_seen for an already-registered node.
+func (s *Store) Heartbeat(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE nodes SET last_seen = ? WHERE id = ?`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record heartbeat for node %s: %w", id, err)
+	}
+	return nil
+}
+
+// WorkItem is a single network a worker has claimed to scan.
+type WorkItem struct {
+	NetworkID string `json:"network_id"`
+	CIDR      string `json:"cidr"`
+}
+
+// ClaimWork assigns up to limit unclaimed (or lease-expired) networks to
+// nodeID. It uses networks.port_scan_started_at as a lease timestamp, the
+// same convention devices already use: a network is claimable if it has
+// never been claimed, or its lease is older than the store's
+// LeaseDuration, which is how a crashed worker's claim expires and becomes
+// available again. The claiming UPDATE re-checks the same claimability
+// condition the initial SELECT used, so a network another concurrent
+// ClaimWork call already won is dropped rather than stolen back.
+func (s *Store) ClaimWork(ctx context.Context, nodeID string, limit int) ([]WorkItem, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin claim transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	leaseExpiry := time.Now().Add(-s.LeaseDuration)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, cidr FROM networks
+		WHERE assigned_node_id IS NULL OR port_scan_started_at IS NULL OR port_scan_started_at < ?
+		LIMIT ?`,
+		leaseExpiry, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query claimable networks: %w", err)
+	}
+
+	var items []WorkItem
+	for rows.Next() {
+		var item WorkItem
+		if err := rows.Scan(&item.NetworkID, &item.CIDR); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan claimable network: %w", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate claimable networks: %w", err)
+	}
+
+	now := time.Now()
+	claimed := items[:0]
+	for _, item := range items {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE networks SET assigned_node_id = ?, port_scan_started_at = ?
+			WHERE id = ? AND (assigned_node_id IS NULL OR port_scan_started_at IS NULL OR port_scan_started_at < ?)`,
+			nodeID, now, item.NetworkID, leaseExpiry,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim network %s: %w", item.NetworkID, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check claim result for network %s: %w", item.NetworkID, err)
+		}
+		if affected == 1 {
+			claimed = append(claimed, item)
+		}
+		// affected == 0 means another transaction claimed this network
+		// between our SELECT and this UPDATE; drop it rather than
+		// returning a network we didn't actually win.
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit claimed networks: %w", err)
+	}
+	return claimed, nil
+}
+
+// PortResult is a single open port a worker found on a device.
+type PortResult struct {
+	Number   string `json:"number"`
+	Protocol string `json:"protocol"`
+	State    string `json:"state"`
+	Service  string `json:"service"`
+}
+
+// WebServiceResult is a single HTTP/HTTPS service a worker probed on a
+// device, mirroring the pd pipeline's httpx output.
+type WebServiceResult struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Server      string `json:"server"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Port        int    `json:"port"`
+	Protocol    string `json:"protocol"`
+}
+
+// DeviceResult is the subset of a scanned device a worker reports back.
+type DeviceResult struct {
+	IPv4        string             `json:"ipv4"`
+	MAC         string             `json:"mac"`
+	Ports       []PortResult       `json:"ports"`
+	WebServices []WebServiceResult `json:"web_services"`
+}
+
+// VulnerabilityResult is a single finding reported back by a worker.
+type VulnerabilityResult struct {
+	DeviceIPv4 string `json:"device_ipv4"`
+	Name       string `json:"name"`
+	Severity   string `json:"severity"`
+}
+
+// SubmitResultsRequest carries everything a worker discovered while
+// scanning its claimed networks.
+type SubmitResultsRequest struct {
+	NodeID          string                `json:"node_id"`
+	Devices         []DeviceResult        `json:"devices"`
+	Vulnerabilities []VulnerabilityResult `json:"vulnerabilities"`
+}
+
+// MergeResults merges a worker's findings into the coordinator's own
+// devices, ports, web_services and vulnerabilities tables. Devices are
+// deduplicated using the same ipv4 unique index the rest of the app relies
+// on; mac is updated in place on conflict. Ports and web_services are
+// associated with the merged device's ID, looked up by ipv4 before the
+// upsert since SQLite's ON CONFLICT clause doesn't hand back the winning
+// row's ID directly. Once the merge commits, it runs Pipeline (if set)
+// against every merged device, the same way a local device scan would, so
+// worker-reported devices get subdomain enumeration, httpx probing and
+// nuclei scanning too.
+func (s *Store) MergeResults(ctx context.Context, req SubmitResultsRequest) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin merge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var mergedDevices []mergedDevice
+	var pendingEvents []pendingEvent
+	for _, d := range req.Devices {
+		var existingID string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM devices WHERE ipv4 = ?`, d.IPv4).Scan(&existingID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up existing device %s from node %s: %w", d.IPv4, req.NodeID, err)
+		}
+		isNew := err == sql.ErrNoRows
+
+		newID := uuid.NewString()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO devices (id, name, ipv4, mac, status, created_at, updated_at, last_seen_online_at)
+			VALUES (?, ?, ?, ?, 'online', ?, ?, ?)
+			ON CONFLICT(ipv4) DO UPDATE SET
+				mac = excluded.mac,
+				status = 'online',
+				updated_at = excluded.updated_at,
+				last_seen_online_at = excluded.last_seen_online_at`,
+			newID, d.IPv4, d.IPv4, d.MAC, now, now, now,
+		); err != nil {
+			return fmt.Errorf("failed to merge device %s from node %s: %w", d.IPv4, req.NodeID, err)
+		}
+
+		deviceID := existingID
+		if isNew {
+			deviceID = newID
+			pendingEvents = append(pendingEvents, pendingEvent{
+				eventType: outputs.EventDeviceDiscovered,
+				severity:  outputs.SeverityInfo,
+				deviceID:  deviceID,
+				data: map[string]interface{}{
+					"ipv4": d.IPv4,
+					"node": req.NodeID,
+				},
+			})
+		}
+		mergedDevices = append(mergedDevices, mergedDevice{id: deviceID, ipv4: d.IPv4})
+
+		for _, p := range d.Ports {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO ports (device_id, number, protocol, state, service, scanned_at)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				deviceID, p.Number, p.Protocol, p.State, p.Service, now,
+			); err != nil {
+				return fmt.Errorf("failed to merge port %s/%s for device %s from node %s: %w", p.Number, p.Protocol, d.IPv4, req.NodeID, err)
+			}
+			if p.State == "" || p.State == "open" {
+				pendingEvents = append(pendingEvents, pendingEvent{
+					eventType: outputs.EventPortOpen,
+					severity:  outputs.SeverityInfo,
+					deviceID:  deviceID,
+					data: map[string]interface{}{
+						"number":   p.Number,
+						"protocol": p.Protocol,
+						"node":     req.NodeID,
+					},
+				})
+			}
+		}
+
+		for _, ws := range d.WebServices {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO web_services (device_id, url, title, server, status_code, content_type, size, port, protocol, scanned_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				deviceID, ws.URL, ws.Title, ws.Server, ws.StatusCode, ws.ContentType, ws.Size, ws.Port, ws.Protocol, now,
+			); err != nil {
+				return fmt.Errorf("failed to merge web service %s for device %s from node %s: %w", ws.URL, d.IPv4, req.NodeID, err)
+			}
+		}
+	}
+
+	for _, v := range req.Vulnerabilities {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO vulnerabilities (id, target, name, severity, discovered_at)
+			VALUES (?, ?, ?, ?, ?)`,
+			uuid.NewString(), v.DeviceIPv4, v.Name, v.Severity, now,
+		); err != nil {
+			return fmt.Errorf("failed to merge vulnerability %s from node %s: %w", v.Name, req.NodeID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merged results: %w", err)
+	}
+
+	// Events are only emitted once the merge has actually committed, so a
+	// dispatcher subscriber never hears about a device or port that a later
+	// failure in this same call rolled back.
+	for _, event := range pendingEvents {
+		s.emit(event.eventType, event.severity, event.deviceID, event.data)
+	}
+
+	if s.Pipeline != nil {
+		for _, d := range mergedDevices {
+			if err := s.Pipeline.RunForDevice(ctx, s.db, d.id, "", []string{d.ipv4}); err != nil {
+				log.Printf("pipeline run failed for device %s from node %s: %v", d.ipv4, req.NodeID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// pendingEvent is an event MergeResults has decided to emit but hasn't yet,
+// because its merge transaction hadn't committed. Deferring construction
+// this way means the event carries whatever deviceID the merge actually
+// assigned, without needing a second pass over the request.
+type pendingEvent struct {
+	eventType outputs.EventType
+	severity  outputs.Severity
+	deviceID  string
+	data      map[string]interface{}
+}
+
+// emit forwards event to Dispatcher, if one is configured, mirroring
+// pd.Pipeline's own emit helper.
+func (s *Store) emit(eventType outputs.EventType, severity outputs.Severity, deviceID string, data map[string]interface{}) {
+	if s.Dispatcher == nil {
+		return
+	}
+	s.Dispatcher.Emit(outputs.Event{
+		Type:     eventType,
+		Severity: severity,
+		DeviceID: deviceID,
+		Data:     data,
+	})
+}
+
+// mergedDevice is a device MergeResults just created or refreshed, carried
+// out of its transaction so the pipeline can run against it afterwards
+// without holding the merge transaction open for network calls.
+type mergedDevice struct {
+	id   string
+	ipv4 string
+}
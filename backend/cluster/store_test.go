@@ -0,0 +1,317 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"reconya-ai/db/migrations"
+	"reconya-ai/outputs"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// Every connection to ":memory:" opens its own empty database, so keep
+	// the pool to one connection or concurrent callers would see no schema.
+	db.SetMaxOpenConns(1)
+	if err := migrations.Apply(db); err != nil {
+		t.Fatalf("failed to apply schema: %v", err)
+	}
+	return db
+}
+
+func insertNetwork(t *testing.T, db *sql.DB, id, cidr string) {
+	t.Helper()
+	if _, err := db.Exec(`INSERT INTO networks (id, cidr) VALUES (?, ?)`, id, cidr); err != nil {
+		t.Fatalf("failed to insert network: %v", err)
+	}
+}
+
+func TestStore_ClaimWork_ClaimsUnassignedNetworks(t *testing.T) {
+	db := openTestDB(t)
+	insertNetwork(t, db, "net-1", "10.0.0.0/24")
+	insertNetwork(t, db, "net-2", "10.0.1.0/24")
+
+	s := NewStore(db)
+	items, err := s.ClaimWork(context.Background(), "worker-1", 10)
+	if err != nil {
+		t.Fatalf("ClaimWork() returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+}
+
+func TestStore_ClaimWork_DoesNotReclaimAnActiveLease(t *testing.T) {
+	db := openTestDB(t)
+	insertNetwork(t, db, "net-1", "10.0.0.0/24")
+
+	s := NewStore(db)
+	ctx := context.Background()
+
+	if _, err := s.ClaimWork(ctx, "worker-1", 10); err != nil {
+		t.Fatalf("first ClaimWork() returned error: %v", err)
+	}
+
+	items, err := s.ClaimWork(ctx, "worker-2", 10)
+	if err != nil {
+		t.Fatalf("second ClaimWork() returned error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("second ClaimWork() claimed %d networks while worker-1's lease is still active, want 0", len(items))
+	}
+}
+
+func TestStore_ClaimWork_ReclaimsAnExpiredLease(t *testing.T) {
+	db := openTestDB(t)
+	insertNetwork(t, db, "net-1", "10.0.0.0/24")
+
+	s := NewStore(db)
+	s.LeaseDuration = time.Millisecond
+	ctx := context.Background()
+
+	if _, err := s.ClaimWork(ctx, "worker-1", 10); err != nil {
+		t.Fatalf("first ClaimWork() returned error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	items, err := s.ClaimWork(ctx, "worker-2", 10)
+	if err != nil {
+		t.Fatalf("second ClaimWork() returned error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("second ClaimWork() claimed %d networks after worker-1's lease expired, want 1", len(items))
+	}
+}
+
+// TestStore_ClaimWork_ConcurrentCallsNeverDoubleClaim exercises the race the
+// maintainer flagged: two callers racing to claim the same network must
+// never both win it.
+func TestStore_ClaimWork_ConcurrentCallsNeverDoubleClaim(t *testing.T) {
+	db := openTestDB(t)
+	insertNetwork(t, db, "net-1", "10.0.0.0/24")
+
+	s := NewStore(db)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([][]WorkItem, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodeID := "worker-1"
+			if i == 1 {
+				nodeID = "worker-2"
+			}
+			items, err := s.ClaimWork(ctx, nodeID, 10)
+			if err != nil {
+				t.Errorf("ClaimWork() returned error: %v", err)
+				return
+			}
+			results[i] = items
+		}(i)
+	}
+	wg.Wait()
+
+	total := len(results[0]) + len(results[1])
+	if total != 1 {
+		t.Fatalf("total networks claimed across both racing calls = %d, want 1", total)
+	}
+}
+
+func TestStore_MergeResults_InsertsNewDeviceWithPortsAndWebServices(t *testing.T) {
+	db := openTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	req := SubmitResultsRequest{
+		NodeID: "worker-1",
+		Devices: []DeviceResult{
+			{
+				IPv4: "10.0.0.5",
+				MAC:  "aa:bb:cc:dd:ee:ff",
+				Ports: []PortResult{
+					{Number: "22", Protocol: "tcp", State: "open", Service: "ssh"},
+				},
+				WebServices: []WebServiceResult{
+					{URL: "http://10.0.0.5", StatusCode: 200, Port: 80, Protocol: "http"},
+				},
+			},
+		},
+		Vulnerabilities: []VulnerabilityResult{
+			{DeviceIPv4: "10.0.0.5", Name: "CVE-TEST", Severity: "high"},
+		},
+	}
+
+	if err := s.MergeResults(ctx, req); err != nil {
+		t.Fatalf("MergeResults() returned error: %v", err)
+	}
+
+	var mac string
+	if err := db.QueryRow(`SELECT mac FROM devices WHERE ipv4 = ?`, "10.0.0.5").Scan(&mac); err != nil {
+		t.Fatalf("failed to read merged device: %v", err)
+	}
+	if mac != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("device.mac = %q, want %q", mac, "aa:bb:cc:dd:ee:ff")
+	}
+
+	var portCount, webServiceCount, vulnCount int
+	db.QueryRow(`SELECT COUNT(*) FROM ports`).Scan(&portCount)
+	db.QueryRow(`SELECT COUNT(*) FROM web_services`).Scan(&webServiceCount)
+	db.QueryRow(`SELECT COUNT(*) FROM vulnerabilities`).Scan(&vulnCount)
+	if portCount != 1 {
+		t.Errorf("portCount = %d, want 1", portCount)
+	}
+	if webServiceCount != 1 {
+		t.Errorf("webServiceCount = %d, want 1", webServiceCount)
+	}
+	if vulnCount != 1 {
+		t.Errorf("vulnCount = %d, want 1", vulnCount)
+	}
+}
+
+func TestStore_MergeResults_ReusesExistingDeviceByIPv4(t *testing.T) {
+	db := openTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	first := SubmitResultsRequest{NodeID: "worker-1", Devices: []DeviceResult{{IPv4: "10.0.0.5", MAC: "aa:bb:cc:dd:ee:ff"}}}
+	if err := s.MergeResults(ctx, first); err != nil {
+		t.Fatalf("first MergeResults() returned error: %v", err)
+	}
+
+	second := SubmitResultsRequest{NodeID: "worker-2", Devices: []DeviceResult{{IPv4: "10.0.0.5", MAC: "11:22:33:44:55:66"}}}
+	if err := s.MergeResults(ctx, second); err != nil {
+		t.Fatalf("second MergeResults() returned error: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM devices WHERE ipv4 = ?`, "10.0.0.5").Scan(&count); err != nil {
+		t.Fatalf("failed to count devices: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("device count for 10.0.0.5 = %d, want 1 (expected update-in-place, not a duplicate)", count)
+	}
+
+	var mac string
+	db.QueryRow(`SELECT mac FROM devices WHERE ipv4 = ?`, "10.0.0.5").Scan(&mac)
+	if mac != "11:22:33:44:55:66" {
+		t.Errorf("device.mac after second merge = %q, want %q", mac, "11:22:33:44:55:66")
+	}
+}
+
+// recordingOutput records every event it's given; used to assert which
+// events a Store emits without a real destination.
+type recordingOutput struct {
+	mu     sync.Mutex
+	events []outputs.Event
+}
+
+func (r *recordingOutput) Name() string { return "recording" }
+func (r *recordingOutput) Emit(ctx context.Context, event outputs.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+func (r *recordingOutput) Close() error { return nil }
+
+func (r *recordingOutput) types() []outputs.EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]outputs.EventType, len(r.events))
+	for i, e := range r.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestStore_MergeResults_EmitsDeviceDiscoveredAndPortOpenForNewDevices(t *testing.T) {
+	db := openTestDB(t)
+	out := &recordingOutput{}
+	dispatcher := outputs.NewDispatcher("test-instance")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher.Register(ctx, out, outputs.Filter{}, 10)
+	defer dispatcher.Close()
+
+	s := NewStore(db)
+	s.Dispatcher = dispatcher
+
+	req := SubmitResultsRequest{
+		NodeID: "worker-1",
+		Devices: []DeviceResult{
+			{
+				IPv4:  "10.0.0.5",
+				Ports: []PortResult{{Number: "22", Protocol: "tcp", State: "open"}},
+			},
+		},
+	}
+	if err := s.MergeResults(context.Background(), req); err != nil {
+		t.Fatalf("MergeResults() returned error: %v", err)
+	}
+
+	// Give the dispatcher's delivery goroutine a moment to drain the queue.
+	time.Sleep(10 * time.Millisecond)
+
+	types := out.types()
+	if len(types) != 2 {
+		t.Fatalf("got %d events, want 2 (device discovered + port open): %v", len(types), types)
+	}
+	if types[0] != outputs.EventDeviceDiscovered {
+		t.Errorf("types[0] = %q, want %q", types[0], outputs.EventDeviceDiscovered)
+	}
+	if types[1] != outputs.EventPortOpen {
+		t.Errorf("types[1] = %q, want %q", types[1], outputs.EventPortOpen)
+	}
+}
+
+func TestStore_MergeResults_DoesNotEmitDeviceDiscoveredForAnExistingDevice(t *testing.T) {
+	db := openTestDB(t)
+	out := &recordingOutput{}
+	dispatcher := outputs.NewDispatcher("test-instance")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher.Register(ctx, out, outputs.Filter{}, 10)
+	defer dispatcher.Close()
+
+	s := NewStore(db)
+	s.Dispatcher = dispatcher
+
+	if err := s.MergeResults(context.Background(), SubmitResultsRequest{
+		NodeID:  "worker-1",
+		Devices: []DeviceResult{{IPv4: "10.0.0.5"}},
+	}); err != nil {
+		t.Fatalf("first MergeResults() returned error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if err := s.MergeResults(context.Background(), SubmitResultsRequest{
+		NodeID:  "worker-2",
+		Devices: []DeviceResult{{IPv4: "10.0.0.5"}},
+	}); err != nil {
+		t.Fatalf("second MergeResults() returned error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	types := out.types()
+	discovered := 0
+	for _, tp := range types {
+		if tp == outputs.EventDeviceDiscovered {
+			discovered++
+		}
+	}
+	if discovered != 1 {
+		t.Errorf("EventDeviceDiscovered fired %d times across both merges, want 1 (only the first merge created the device)", discovered)
+	}
+}
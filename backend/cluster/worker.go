@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Scanner performs the actual scan of a claimed CIDR range. It's expressed
+// as an interface here, the same way Store.Pipeline is, so this package
+// doesn't have to import whatever heavyweight scanning package produces the
+// results - Worker only needs to turn a CIDR into a batch of findings to
+// report back to the coordinator.
+type Scanner interface {
+	ScanNetwork(ctx context.Context, cidr string) ([]DeviceResult, []VulnerabilityResult, error)
+}
+
+// Worker polls a coordinator for claimed work, scans each claimed network
+// with Scanner, and submits the results back. It's the client-side
+// counterpart to the HTTP handlers RegisterRoutes mounts.
+type Worker struct {
+	// CoordinatorURL is the coordinator's base address, e.g.
+	// "http://coordinator:8080".
+	CoordinatorURL string
+	NodeID         string
+	Address        string
+	Capabilities   []string
+	Scanner        Scanner
+
+	// ClaimLimit bounds how many networks are requested per poll. Defaults
+	// to 1 if unset.
+	ClaimLimit int
+	// PollInterval is how often to poll for work once idle. Defaults to
+	// DefaultPollInterval if unset.
+	PollInterval time.Duration
+	// HeartbeatInterval is how often to refresh this node's last_seen.
+	// Defaults to DefaultHeartbeatInterval if unset.
+	HeartbeatInterval time.Duration
+
+	httpClient *http.Client
+}
+
+// DefaultPollInterval is how often a worker checks for new work when it
+// has none claimed.
+const DefaultPollInterval = 10 * time.Second
+
+// DefaultHeartbeatInterval is how often a worker refreshes its last_seen
+// with the coordinator.
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// NewWorker returns a Worker that scans networks with scanner and reports
+// to coordinatorURL as nodeID.
+func NewWorker(coordinatorURL, nodeID, address string, capabilities []string, scanner Scanner) *Worker {
+	return &Worker{
+		CoordinatorURL: coordinatorURL,
+		NodeID:         nodeID,
+		Address:        address,
+		Capabilities:   capabilities,
+		Scanner:        scanner,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run registers the worker with the coordinator, then loops claiming and
+// scanning networks and sending heartbeats until ctx is canceled. Scan
+// failures for a single network are logged and skipped rather than
+// aborting the loop, so one bad target doesn't stop the worker from
+// picking up the rest of its claimed batch.
+func (w *Worker) Run(ctx context.Context) error {
+	if err := w.registerNode(ctx); err != nil {
+		return fmt.Errorf("failed to register with coordinator: %w", err)
+	}
+
+	pollInterval := w.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	heartbeatInterval := w.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	heartbeatTicker := time.NewTicker(heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeatTicker.C:
+			if err := w.heartbeat(ctx); err != nil {
+				log.Printf("cluster worker %s: heartbeat failed: %v", w.NodeID, err)
+			}
+		default:
+		}
+
+		items, err := w.claimWork(ctx)
+		if err != nil {
+			log.Printf("cluster worker %s: claim failed: %v", w.NodeID, err)
+			items = nil
+		}
+
+		if len(items) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		for _, item := range items {
+			devices, vulns, err := w.Scanner.ScanNetwork(ctx, item.CIDR)
+			if err != nil {
+				log.Printf("cluster worker %s: scan of %s failed: %v", w.NodeID, item.CIDR, err)
+				continue
+			}
+
+			req := SubmitResultsRequest{NodeID: w.NodeID, Devices: devices, Vulnerabilities: vulns}
+			if err := w.submitResults(ctx, req); err != nil {
+				log.Printf("cluster worker %s: submitting results for %s failed: %v", w.NodeID, item.CIDR, err)
+			}
+		}
+	}
+}
+
+func (w *Worker) registerNode(ctx context.Context) error {
+	return w.post(ctx, "/api/cluster/nodes", registerNodeRequest{
+		ID:           w.NodeID,
+		Address:      w.Address,
+		Capabilities: w.Capabilities,
+	}, nil)
+}
+
+func (w *Worker) heartbeat(ctx context.Context) error {
+	return w.post(ctx, fmt.Sprintf("/api/cluster/nodes/%s/heartbeat", w.NodeID), nil, nil)
+}
+
+func (w *Worker) claimWork(ctx context.Context) ([]WorkItem, error) {
+	limit := w.ClaimLimit
+	if limit <= 0 {
+		limit = 1
+	}
+
+	var items []WorkItem
+	if err := w.post(ctx, "/api/cluster/claim", claimWorkRequest{NodeID: w.NodeID, Limit: limit}, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (w *Worker) submitResults(ctx context.Context, req SubmitResultsRequest) error {
+	return w.post(ctx, "/api/cluster/results", req, nil)
+}
+
+// post sends body (if non-nil) as JSON to path on the coordinator and, if
+// out is non-nil, decodes the JSON response into it.
+func (w *Worker) post(ctx context.Context, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request for %s: %w", path, err)
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.CoordinatorURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response from %s: %w", path, err)
+		}
+	}
+	return nil
+}
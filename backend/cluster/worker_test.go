@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// fakeScanner reports a single fixed device for every network it's asked
+// to scan, recording which CIDRs it was called with.
+type fakeScanner struct {
+	scanned []string
+}
+
+func (f *fakeScanner) ScanNetwork(ctx context.Context, cidr string) ([]DeviceResult, []VulnerabilityResult, error) {
+	f.scanned = append(f.scanned, cidr)
+	return []DeviceResult{{IPv4: "10.0.0.5"}}, nil, nil
+}
+
+func TestWorker_Run_ClaimsScansAndSubmitsThenIdles(t *testing.T) {
+	db := openTestDB(t)
+	insertNetwork(t, db, "net-1", "10.0.0.0/24")
+	store := NewStore(db)
+
+	router := mux.NewRouter()
+	RegisterRoutes(router, store)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	scanner := &fakeScanner{}
+	w := NewWorker(server.URL, "worker-1", "worker-1:9000", []string{"can-run-nuclei"}, scanner)
+	w.PollInterval = 5 * time.Millisecond
+	w.HeartbeatInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if len(scanner.scanned) != 1 || scanner.scanned[0] != "10.0.0.0/24" {
+		t.Fatalf("scanner.scanned = %v, want [\"10.0.0.0/24\"]", scanner.scanned)
+	}
+
+	var deviceCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM devices WHERE ipv4 = ?`, "10.0.0.5").Scan(&deviceCount); err != nil {
+		t.Fatalf("failed to count devices: %v", err)
+	}
+	if deviceCount != 1 {
+		t.Fatalf("deviceCount = %d, want 1 (worker's scan result should have been submitted and merged)", deviceCount)
+	}
+
+	var nodeCount int
+	db.QueryRow(`SELECT COUNT(*) FROM nodes WHERE id = ?`, "worker-1").Scan(&nodeCount)
+	if nodeCount != 1 {
+		t.Errorf("nodeCount = %d, want 1 (worker should have registered itself)", nodeCount)
+	}
+}
+
+func TestWorker_Run_StopsOnContextCancelWithNoWork(t *testing.T) {
+	db := openTestDB(t)
+	store := NewStore(db)
+
+	router := mux.NewRouter()
+	RegisterRoutes(router, store)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	scanner := &fakeScanner{}
+	w := NewWorker(server.URL, "worker-1", "worker-1:9000", nil, scanner)
+	w.PollInterval = 5 * time.Millisecond
+	w.HeartbeatInterval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	if len(scanner.scanned) != 0 {
+		t.Errorf("scanner.scanned = %v, want none (no networks were available to claim)", scanner.scanned)
+	}
+}
@@ -0,0 +1,155 @@
+// Package migrations implements a versioned schema-migration framework for
+// reconya's SQLite database, modeled on the approach LXD's node-database
+// uses for its updates: every schema change is a numbered, idempotent
+// function that runs inside its own transaction, and the highest applied
+// index is recorded so it only ever runs once.
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Update applies one schema change. It receives the transaction the
+// migration runner opened for it, so a failure rolls back cleanly.
+type Update func(tx *sql.Tx) error
+
+// Updates is the ordered list of schema migrations. Append new entries to
+// the end; never reorder or remove one that has shipped; the index in
+// this slice (1-based) is the schema version recorded in schema_version.
+var Updates = []Update{
+	updateCoreSchema,
+	updateIPv6Support,
+	updateDeviceFingerprinting,
+	updateNetworkMetadata,
+	updateVulnerabilities,
+	updateSettings,
+	updateRetentionPolicies,
+	updateClusterSupport,
+	updateDeviceSightings,
+	updateWebServiceTLSVersion,
+	updatePortScannedAt,
+}
+
+func ensureVersionTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+	return nil
+}
+
+func currentVersion(tx *sql.Tx) (int, error) {
+	if err := ensureVersionTable(tx); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// Apply brings the database schema up to date by running every update
+// whose index is greater than the recorded schema version, each inside
+// its own transaction. It refuses to run if the database is stamped with
+// a version higher than this binary knows about, since that almost always
+// means a downgrade to an older binary.
+func Apply(db *sql.DB) error {
+	versionTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin schema version check: %w", err)
+	}
+	version, err := currentVersion(versionTx)
+	if err != nil {
+		versionTx.Rollback()
+		return err
+	}
+	if err := versionTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema version check: %w", err)
+	}
+
+	if version > len(Updates) {
+		return fmt.Errorf("database schema is at version %d, this binary only knows about %d updates - refusing to start to avoid a downgrade", version, len(Updates))
+	}
+
+	for i := version; i < len(Updates); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction for schema update %d: %w", i+1, err)
+		}
+
+		if err := Updates[i](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply schema update %d: %w", i+1, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, i+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema version %d: %w", i+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit schema update %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Reset drops the recorded schema version so the next call to Apply
+// replays every update from scratch. It backs the --reset-schema dev flag;
+// updates themselves use CREATE TABLE IF NOT EXISTS and column-existence
+// checks, so replaying them against a database that already has the
+// tables/columns is safe and does not touch existing rows.
+func Reset(db *sql.DB) error {
+	if _, err := db.Exec(`DROP TABLE IF EXISTS schema_version`); err != nil {
+		return fmt.Errorf("failed to reset schema_version: %w", err)
+	}
+	return nil
+}
+
+// columnExists reports whether table already has column, so updates can
+// add a column without relying on SQLite returning a recognizable "already
+// exists" error.
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// addColumnIfMissing adds column to table with the given declaration
+// (e.g. "TEXT", "INTEGER DEFAULT 0") unless it's already there.
+func addColumnIfMissing(tx *sql.Tx, table, column, declaration string) error {
+	exists, err := columnExists(tx, table, column)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s.%s: %w", table, column, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, declaration)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestApply_RunsEveryUpdateOnce(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Apply(db); err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		t.Fatalf("failed to read schema version: %v", err)
+	}
+	if version != len(Updates) {
+		t.Errorf("schema_version = %d, want %d", version, len(Updates))
+	}
+
+	// Applying again should be a no-op, not a re-run of every update.
+	if err := Apply(db); err != nil {
+		t.Fatalf("second Apply() returned error: %v", err)
+	}
+
+	var rowCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&rowCount); err != nil {
+		t.Fatalf("failed to count schema_version rows: %v", err)
+	}
+	if rowCount != len(Updates) {
+		t.Errorf("schema_version has %d rows after re-Apply, want %d", rowCount, len(Updates))
+	}
+}
+
+func TestApply_RefusesDowngrade(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Apply(db); err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_version (version) VALUES (?)`, len(Updates)+1); err != nil {
+		t.Fatalf("failed to stamp future schema version: %v", err)
+	}
+
+	if err := Apply(db); err == nil {
+		t.Error("Apply() succeeded against a database stamped with a future schema version, want error")
+	}
+}
+
+func TestReset_ReplaysUpdatesWithoutError(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Apply(db); err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+	if err := Reset(db); err != nil {
+		t.Fatalf("Reset() returned error: %v", err)
+	}
+	if err := Apply(db); err != nil {
+		t.Fatalf("Apply() after Reset() returned error: %v", err)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		t.Fatalf("failed to read schema version: %v", err)
+	}
+	if version != len(Updates) {
+		t.Errorf("schema_version = %d after replay, want %d", version, len(Updates))
+	}
+}
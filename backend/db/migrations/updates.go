@@ -0,0 +1,313 @@
+package migrations
+
+import "database/sql"
+
+// updateCoreSchema creates the tables reconya has shipped with since its
+// first release.
+func updateCoreSchema(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS networks (
+			id TEXT PRIMARY KEY,
+			cidr TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS devices (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			ipv4 TEXT NOT NULL,
+			mac TEXT,
+			vendor TEXT,
+			status TEXT NOT NULL,
+			network_id TEXT,
+			hostname TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			last_seen_online_at TIMESTAMP,
+			port_scan_started_at TIMESTAMP,
+			port_scan_ended_at TIMESTAMP,
+			FOREIGN KEY (network_id) REFERENCES networks(id)
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_ipv4 ON devices(ipv4)`,
+		`CREATE INDEX IF NOT EXISTS idx_devices_mac ON devices(mac)`,
+		`CREATE INDEX IF NOT EXISTS idx_devices_network_id ON devices(network_id)`,
+		`CREATE TABLE IF NOT EXISTS ports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			device_id TEXT NOT NULL,
+			number TEXT NOT NULL,
+			protocol TEXT NOT NULL,
+			state TEXT NOT NULL,
+			service TEXT NOT NULL,
+			FOREIGN KEY (device_id) REFERENCES devices(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS event_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			description TEXT NOT NULL,
+			device_id TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS system_status (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			network_id TEXT,
+			public_ip TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (network_id) REFERENCES networks(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS local_devices (
+			system_status_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			ipv4 TEXT NOT NULL,
+			mac TEXT,
+			vendor TEXT,
+			status TEXT NOT NULL,
+			hostname TEXT,
+			PRIMARY KEY (system_status_id),
+			FOREIGN KEY (system_status_id) REFERENCES system_status(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS web_services (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			device_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			title TEXT,
+			server TEXT,
+			status_code INTEGER NOT NULL,
+			content_type TEXT,
+			size INTEGER,
+			screenshot TEXT,
+			port INTEGER NOT NULL,
+			protocol TEXT NOT NULL,
+			scanned_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (device_id) REFERENCES devices(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_web_services_device_id ON web_services(device_id)`,
+		`CREATE TABLE IF NOT EXISTS geolocation_cache (
+			id TEXT PRIMARY KEY,
+			ip TEXT NOT NULL UNIQUE,
+			city TEXT,
+			region TEXT,
+			country TEXT,
+			country_code TEXT,
+			latitude REAL,
+			longitude REAL,
+			timezone TEXT,
+			isp TEXT,
+			source TEXT NOT NULL DEFAULT 'api',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_geolocation_cache_ip ON geolocation_cache(ip)`,
+		`CREATE INDEX IF NOT EXISTS idx_geolocation_cache_expires_at ON geolocation_cache(expires_at)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateIPv6Support adds the device IPv6 columns and their lookup indexes.
+func updateIPv6Support(tx *sql.Tx) error {
+	columns := []struct{ name, declaration string }{
+		{"ipv6_link_local", "TEXT"},
+		{"ipv6_unique_local", "TEXT"},
+		{"ipv6_global", "TEXT"},
+		{"ipv6_addresses", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(tx, "devices", c.name, c.declaration); err != nil {
+			return err
+		}
+	}
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_devices_ipv6_link_local ON devices(ipv6_link_local)`,
+		`CREATE INDEX IF NOT EXISTS idx_devices_ipv6_unique_local ON devices(ipv6_unique_local)`,
+		`CREATE INDEX IF NOT EXISTS idx_devices_ipv6_global ON devices(ipv6_global)`,
+	}
+	for _, stmt := range indexes {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateDeviceFingerprinting adds OS/device fingerprinting columns plus the
+// comment and web_scan_ended_at columns used by device editing and the web
+// scan cooldown.
+func updateDeviceFingerprinting(tx *sql.Tx) error {
+	columns := []struct{ name, declaration string }{
+		{"web_scan_ended_at", "TIMESTAMP"},
+		{"device_type", "TEXT"},
+		{"os_name", "TEXT"},
+		{"os_version", "TEXT"},
+		{"os_family", "TEXT"},
+		{"os_confidence", "INTEGER"},
+		{"comment", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(tx, "devices", c.name, c.declaration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateNetworkMetadata adds the extended network-management columns.
+func updateNetworkMetadata(tx *sql.Tx) error {
+	columns := []struct{ name, declaration string }{
+		{"name", "TEXT"},
+		{"description", "TEXT"},
+		{"status", "TEXT DEFAULT 'active'"},
+		{"last_scanned_at", "TIMESTAMP"},
+		{"device_count", "INTEGER DEFAULT 0"},
+		{"created_at", "TIMESTAMP"},
+		{"updated_at", "TIMESTAMP"},
+		{"ipv6_prefix", "TEXT"},
+		{"address_family", "TEXT DEFAULT 'ipv4'"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(tx, "networks", c.name, c.declaration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateVulnerabilities creates the vulnerabilities table populated by the
+// nuclei scan stage of the scan pipeline.
+func updateVulnerabilities(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS vulnerabilities (
+			id TEXT PRIMARY KEY,
+			device_id TEXT,
+			target TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			severity TEXT,
+			discovered_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (device_id) REFERENCES devices(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_vulnerabilities_device_id ON vulnerabilities(device_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSettings creates the per-user settings table.
+func updateSettings(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS settings (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			screenshots_enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			UNIQUE(user_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_settings_user_id ON settings(user_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRetentionPolicies creates the table backing the retention package's
+// configurable TTLs for scan artifacts.
+func updateRetentionPolicies(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS retention_policies (
+			name TEXT PRIMARY KEY,
+			target_table TEXT NOT NULL,
+			duration_seconds INTEGER NOT NULL,
+			max_rows INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateClusterSupport adds the coordinator/worker clustering tables and
+// columns: a nodes table tracking worker heartbeats, plus the columns on
+// networks a coordinator uses to assign and lease out CIDR ranges.
+func updateClusterSupport(tx *sql.Tx) error {
+	if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS nodes (
+		id TEXT PRIMARY KEY,
+		address TEXT NOT NULL,
+		capabilities TEXT,
+		last_seen TIMESTAMP NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	columns := []struct{ name, declaration string }{
+		{"assigned_node_id", "TEXT"},
+		{"port_scan_started_at", "TIMESTAMP"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(tx, "networks", c.name, c.declaration); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_networks_assigned_node_id ON networks(assigned_node_id)`); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateDeviceSightings creates the table the discovery.Merger uses to
+// record every raw observation behind a merged devices row, so the merge
+// is reproducible and auditable.
+func updateDeviceSightings(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS device_sightings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			device_id TEXT NOT NULL,
+			source TEXT NOT NULL,
+			field TEXT NOT NULL,
+			value TEXT NOT NULL,
+			observed_at TIMESTAMP NOT NULL,
+			confidence INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (device_id) REFERENCES devices(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_device_sightings_device_id ON device_sightings(device_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_device_sightings_device_id_field ON device_sightings(device_id, field)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateWebServiceTLSVersion adds the TLS version column the httpx probe
+// stage captures alongside status code, title and server header.
+func updateWebServiceTLSVersion(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "web_services", "tls_version", "TEXT")
+}
+
+// updatePortScannedAt adds the timestamp column retention needs to expire
+// historical port scans, matching the scanned_at/discovered_at convention
+// the other scan-artifact tables already use.
+func updatePortScannedAt(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "ports", "scanned_at", "TIMESTAMP")
+}
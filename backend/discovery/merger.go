@@ -0,0 +1,238 @@
+package discovery
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reconya-ai/models"
+	"reconya-ai/outputs"
+)
+
+// deviceColumn maps a key Field to the devices column it's mirrored into.
+var deviceColumn = map[Field]string{
+	FieldMAC:      "mac",
+	FieldIPv4:     "ipv4",
+	FieldIPv6:     "ipv6_global",
+	FieldHostname: "hostname",
+}
+
+// keyFields lists, in priority order, which field identifies a device:
+// a MAC match wins over an IPv4 match, which wins over an IPv6 global
+// match, which wins over hostname.
+var keyFields = []Field{FieldMAC, FieldIPv4, FieldIPv6, FieldHostname}
+
+// TieBreaker picks a winner between two sightings of the same device/field
+// with equal confidence.
+type TieBreaker func(a, b Sighting) Sighting
+
+func newestWins(a, b Sighting) Sighting {
+	if b.ObservedAt.After(a.ObservedAt) {
+		return b
+	}
+	return a
+}
+
+// Merger reconciles sightings from multiple scanners into the devices
+// table, keeping every raw sighting in device_sightings for audit.
+// Dispatcher is optional; when set, Submit emits EventDeviceDiscovered for
+// every device it creates.
+type Merger struct {
+	db         *sql.DB
+	TieBreaker TieBreaker
+	Dispatcher *outputs.Dispatcher
+}
+
+// NewMerger returns a Merger that resolves same-confidence conflicts by
+// preferring whichever sighting was observed most recently. Set
+// TieBreaker to override that.
+func NewMerger(db *sql.DB) *Merger {
+	return &Merger{db: db, TieBreaker: newestWins}
+}
+
+// Observation is everything one scanner reported about one device at one
+// point in time - e.g. Nmap seeing a MAC and an IPv4 together.
+type Observation struct {
+	Source     Source
+	Fields     map[Field]string
+	ObservedAt time.Time
+	Confidence int
+}
+
+// Submit resolves an observation against the matching device - using the
+// MAC -> IPv4 -> IPv6 global -> hostname priority chain across whichever
+// of those fields the observation carries - creating one if none match,
+// then applies each key field (MAC, IPv4, IPv6 global, hostname) to the
+// devices row if it wins against whatever is already recorded for it, and
+// records every field, key or not, as a raw sighting.
+// It returns the device ID the observation resolved to.
+func (m *Merger) Submit(ctx context.Context, o Observation) (string, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin sighting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deviceID, created, err := m.resolveDevice(ctx, tx, o)
+	if err != nil {
+		return "", err
+	}
+
+	for field, value := range o.Fields {
+		if value == "" {
+			continue
+		}
+		sighting := Sighting{
+			DeviceID:   deviceID,
+			Source:     o.Source,
+			Field:      field,
+			Value:      value,
+			ObservedAt: o.ObservedAt,
+			Confidence: o.Confidence,
+		}
+
+		if err := m.applyField(ctx, tx, sighting); err != nil {
+			return "", err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO device_sightings (device_id, source, field, value, observed_at, confidence)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			sighting.DeviceID, sighting.Source, sighting.Field, sighting.Value, sighting.ObservedAt, sighting.Confidence,
+		); err != nil {
+			return "", fmt.Errorf("failed to record sighting: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit sighting: %w", err)
+	}
+
+	if created {
+		m.emit(outputs.EventDeviceDiscovered, outputs.SeverityInfo, deviceID, map[string]interface{}{
+			"source": string(o.Source),
+		})
+	}
+	return deviceID, nil
+}
+
+// resolveDevice walks keyFields in priority order looking for a device
+// already carrying one of the observation's values in its matching
+// column, creating one if nothing matches. The returned bool reports
+// whether it created a new device.
+func (m *Merger) resolveDevice(ctx context.Context, tx *sql.Tx, o Observation) (string, bool, error) {
+	for _, field := range keyFields {
+		value, ok := o.Fields[field]
+		if !ok || value == "" {
+			continue
+		}
+
+		column := deviceColumn[field]
+		var id string
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT id FROM devices WHERE %s = ?`, column), value).Scan(&id)
+		if err == nil {
+			return id, false, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", false, fmt.Errorf("failed to look up device by %s: %w", field, err)
+		}
+	}
+
+	id := uuid.NewString()
+
+	// devices.ipv4 is NOT NULL and UNIQUE, but a pure mDNS/SSDP/hostname-only
+	// observation may carry no IPv4 at all. Fall back to a placeholder keyed
+	// on the new device's own ID instead of an empty string, so a second
+	// IPv4-less device doesn't collide with the first on insert.
+	ipv4 := o.Fields[FieldIPv4]
+	if ipv4 == "" {
+		ipv4 = "unknown:" + id
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO devices (id, name, ipv4, status, created_at, updated_at)
+		VALUES (?, ?, ?, 'online', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		id, o.identity(), ipv4,
+	); err != nil {
+		return "", false, fmt.Errorf("failed to create device for observation: %w", err)
+	}
+	return id, true, nil
+}
+
+// emit forwards event to Dispatcher, if one is configured, mirroring
+// pd.Pipeline's own emit helper.
+func (m *Merger) emit(eventType outputs.EventType, severity outputs.Severity, deviceID string, data map[string]interface{}) {
+	if m.Dispatcher == nil {
+		return
+	}
+	m.Dispatcher.Emit(outputs.Event{
+		Type:     eventType,
+		Severity: severity,
+		DeviceID: deviceID,
+		Data:     data,
+	})
+}
+
+// identity picks the highest-priority identifying value an observation
+// carries, to use as a new device's placeholder name.
+func (o Observation) identity() string {
+	for _, field := range keyFields {
+		if value := o.Fields[field]; value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// applyField writes s.Value into the matching devices column, but only if
+// no prior sighting for that device/field has strictly higher confidence -
+// equal confidence is resolved by TieBreaker, newest-high-confidence-wins
+// by default.
+func (m *Merger) applyField(ctx context.Context, tx *sql.Tx, s Sighting) error {
+	column, ok := deviceColumn[s.Field]
+	if !ok {
+		return nil // non-key fields (e.g. vendor) aren't mirrored onto devices
+	}
+
+	var current Sighting
+	err := tx.QueryRowContext(ctx, `
+		SELECT source, value, observed_at, confidence FROM device_sightings
+		WHERE device_id = ? AND field = ?
+		ORDER BY confidence DESC, observed_at DESC LIMIT 1`,
+		s.DeviceID, s.Field,
+	).Scan(&current.Source, &current.Value, &current.ObservedAt, &current.Confidence)
+
+	winner := s
+	switch {
+	case err == sql.ErrNoRows:
+		// no prior sighting for this field, s wins by default
+	case err != nil:
+		return fmt.Errorf("failed to look up existing sightings for %s: %w", s.Field, err)
+	case current.Confidence > s.Confidence:
+		winner = current
+	case current.Confidence == s.Confidence:
+		winner = m.TieBreaker(current, s)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE devices SET %s = ? WHERE id = ?`, column), winner.Value, s.DeviceID); err != nil {
+		return fmt.Errorf("failed to update devices.%s: %w", column, err)
+	}
+	return nil
+}
+
+// Device fetches the merged device row the same way the rest of the app
+// reads it, so callers can see what the merge produced.
+func (m *Merger) Device(ctx context.Context, deviceID string) (models.Device, error) {
+	var d models.Device
+	err := m.db.QueryRowContext(ctx, `
+		SELECT id, name, ipv4, mac, vendor, status, hostname, created_at, updated_at
+		FROM devices WHERE id = ?`, deviceID,
+	).Scan(&d.ID, &d.Name, &d.IPv4, &d.MAC, &d.Vendor, &d.Status, &d.Hostname, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return models.Device{}, fmt.Errorf("failed to load merged device %s: %w", deviceID, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,135 @@
+package discovery
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"reconya-ai/db/migrations"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrations.Apply(db); err != nil {
+		t.Fatalf("failed to apply schema: %v", err)
+	}
+	return db
+}
+
+func TestMerger_Submit_IPv4LessObservationsDoNotCollide(t *testing.T) {
+	db := openTestDB(t)
+	m := NewMerger(db)
+	ctx := context.Background()
+
+	first, err := m.Submit(ctx, Observation{
+		Source:     SourceMDNS,
+		Fields:     map[Field]string{FieldHostname: "printer.local"},
+		ObservedAt: time.Now(),
+		Confidence: 1,
+	})
+	if err != nil {
+		t.Fatalf("first Submit() returned error: %v", err)
+	}
+
+	second, err := m.Submit(ctx, Observation{
+		Source:     SourceSSDP,
+		Fields:     map[Field]string{FieldHostname: "speaker.local"},
+		ObservedAt: time.Now(),
+		Confidence: 1,
+	})
+	if err != nil {
+		t.Fatalf("second Submit() returned error: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("two distinct IPv4-less observations resolved to the same device %s", first)
+	}
+}
+
+func TestMerger_Submit_MatchesExistingDeviceByMAC(t *testing.T) {
+	db := openTestDB(t)
+	m := NewMerger(db)
+	ctx := context.Background()
+
+	id, err := m.Submit(ctx, Observation{
+		Source:     SourceARP,
+		Fields:     map[Field]string{FieldMAC: "aa:bb:cc:dd:ee:ff", FieldIPv4: "10.0.0.5"},
+		ObservedAt: time.Now(),
+		Confidence: 5,
+	})
+	if err != nil {
+		t.Fatalf("first Submit() returned error: %v", err)
+	}
+
+	again, err := m.Submit(ctx, Observation{
+		Source:     SourceNmap,
+		Fields:     map[Field]string{FieldMAC: "aa:bb:cc:dd:ee:ff", FieldHostname: "host.local"},
+		ObservedAt: time.Now(),
+		Confidence: 3,
+	})
+	if err != nil {
+		t.Fatalf("second Submit() returned error: %v", err)
+	}
+
+	if id != again {
+		t.Errorf("observation sharing a MAC resolved to device %s, want %s", again, id)
+	}
+
+	device, err := m.Device(ctx, id)
+	if err != nil {
+		t.Fatalf("Device() returned error: %v", err)
+	}
+	if device.Hostname != "host.local" {
+		t.Errorf("device.Hostname = %q, want %q", device.Hostname, "host.local")
+	}
+}
+
+func TestMerger_Submit_HigherConfidenceWinsOnConflict(t *testing.T) {
+	db := openTestDB(t)
+	m := NewMerger(db)
+	ctx := context.Background()
+
+	id, err := m.Submit(ctx, Observation{
+		Source:     SourceARP,
+		Fields:     map[Field]string{FieldMAC: "11:22:33:44:55:66", FieldHostname: "low-confidence.local"},
+		ObservedAt: time.Now(),
+		Confidence: 1,
+	})
+	if err != nil {
+		t.Fatalf("first Submit() returned error: %v", err)
+	}
+
+	if _, err := m.Submit(ctx, Observation{
+		Source:     SourceNmap,
+		Fields:     map[Field]string{FieldMAC: "11:22:33:44:55:66", FieldHostname: "stale-low-confidence.local"},
+		ObservedAt: time.Now().Add(time.Minute),
+		Confidence: 1,
+	}); err != nil {
+		t.Fatalf("second Submit() returned error: %v", err)
+	}
+
+	if _, err := m.Submit(ctx, Observation{
+		Source:     SourceNmap,
+		Fields:     map[Field]string{FieldMAC: "11:22:33:44:55:66", FieldHostname: "high-confidence.local"},
+		ObservedAt: time.Now().Add(-time.Hour),
+		Confidence: 10,
+	}); err != nil {
+		t.Fatalf("third Submit() returned error: %v", err)
+	}
+
+	device, err := m.Device(ctx, id)
+	if err != nil {
+		t.Fatalf("Device() returned error: %v", err)
+	}
+	if device.Hostname != "high-confidence.local" {
+		t.Errorf("device.Hostname = %q, want %q", device.Hostname, "high-confidence.local")
+	}
+}
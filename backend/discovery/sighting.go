@@ -0,0 +1,42 @@
+// Package discovery reconciles device sightings reported by heterogeneous
+// scanners (ARP, ICMP, mDNS, SSDP, Nmap, subfinder-derived hosts) into a
+// single canonical models.Device, keeping every raw observation around in
+// device_sightings so the merge is reproducible and auditable.
+package discovery
+
+import "time"
+
+// Source identifies which scanner produced a sighting.
+type Source string
+
+const (
+	SourceARP       Source = "arp"
+	SourceICMP      Source = "icmp"
+	SourceMDNS      Source = "mdns"
+	SourceSSDP      Source = "ssdp"
+	SourceNmap      Source = "nmap"
+	SourceSubfinder Source = "subfinder"
+)
+
+// Field is the device attribute a sighting reports a value for.
+type Field string
+
+const (
+	FieldMAC      Field = "mac"
+	FieldIPv4     Field = "ipv4"
+	FieldIPv6     Field = "ipv6_global"
+	FieldHostname Field = "hostname"
+	FieldVendor   Field = "vendor"
+)
+
+// Sighting is a single observation of one field of one device, as reported
+// by one source at one point in time. Confidence follows the same
+// convention as devices.os_confidence: higher is more certain.
+type Sighting struct {
+	DeviceID   string
+	Source     Source
+	Field      Field
+	Value      string
+	ObservedAt time.Time
+	Confidence int
+}
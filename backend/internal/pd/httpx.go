@@ -2,22 +2,96 @@ package pd
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	httpxRunner "github.com/projectdiscovery/httpx/runner"
 )
 
 // HTTPXService is a lightweight wrapper around the httpx runner.
-type HTTPXService struct{}
+type HTTPXService struct {
+	Threads int
+	Timeout int // seconds
+}
 
-// NewHTTPXService creates a new instance of the service.
+// NewHTTPXService creates a new instance of the service with sane defaults.
 func NewHTTPXService() *HTTPXService {
-	return &HTTPXService{}
+	return &HTTPXService{
+		Threads: 25,
+		Timeout: 10,
+	}
+}
+
+// ProbeResult is the subset of an httpx response persisted into the
+// web_services table.
+type ProbeResult struct {
+	URL         string
+	Host        string
+	Port        int
+	Protocol    string
+	StatusCode  int
+	Title       string
+	Server      string
+	ContentType string
+	Size        int
+	TLSVersion  string
 }
 
-// Probe verifies that provided hosts are reachable over HTTP/HTTPS. This
-// placeholder implementation ensures the httpx dependency is pulled in while
-// returning an empty slice.
-func (s *HTTPXService) Probe(ctx context.Context, hosts []string) ([]string, error) {
-	_ = httpxRunner.Options{}
-	return []string{}, nil
+// Probe sends each host through httpx and returns the hosts that answered,
+// with status code, title, server header, content-type, size and TLS
+// version captured for each.
+func (s *HTTPXService) Probe(ctx context.Context, hosts []string) ([]ProbeResult, error) {
+	if len(hosts) == 0 {
+		return []ProbeResult{}, nil
+	}
+
+	var (
+		mu      sync.Mutex
+		results []ProbeResult
+	)
+
+	options := httpxRunner.Options{
+		Methods:         "GET",
+		InputTargetHost: hosts,
+		Threads:         s.Threads,
+		Timeout:         s.Timeout,
+		TLSGrab:         true,
+		Silent:          true,
+		OnResult: func(r httpxRunner.Result) {
+			if r.Err != nil {
+				return
+			}
+
+			result := ProbeResult{
+				URL:         r.URL,
+				Host:        r.Input,
+				Protocol:    r.Scheme,
+				StatusCode:  r.StatusCode,
+				Title:       r.Title,
+				Server:      r.WebServer,
+				ContentType: r.ContentType,
+				Size:        r.ContentLength,
+			}
+			if r.Port != "" {
+				fmt.Sscanf(r.Port, "%d", &result.Port)
+			}
+			if r.TLSData != nil {
+				result.TLSVersion = r.TLSData.Version
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		},
+	}
+
+	runnerInstance, err := httpxRunner.New(&options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create httpx runner: %w", err)
+	}
+	defer runnerInstance.Close()
+
+	runnerInstance.RunEnumeration()
+
+	return results, nil
 }
@@ -2,24 +2,67 @@ package pd
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	nuclei "github.com/projectdiscovery/nuclei/v3/lib"
+	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 
-	nucleiCore "github.com/projectdiscovery/nuclei/v3/pkg/core"
 	"reconya-ai/models"
 )
 
-// NucleiService provides a thin wrapper around the nuclei library.
-type NucleiService struct{}
+// NucleiService provides a thin wrapper around the nuclei SDK.
+type NucleiService struct {
+	// TemplatesDir points nuclei at a custom template directory. Empty
+	// falls back to nuclei's default templates.
+	TemplatesDir string
+	// MinSeverity filters findings (e.g. "low", "medium", "high", "critical").
+	// Empty means no severity filter.
+	MinSeverity string
+}
 
 // NewNucleiService creates a new service instance.
-func NewNucleiService() *NucleiService {
-	return &NucleiService{}
+func NewNucleiService(templatesDir, minSeverity string) *NucleiService {
+	return &NucleiService{
+		TemplatesDir: templatesDir,
+		MinSeverity:  minSeverity,
+	}
 }
 
-// Scan runs nuclei against the provided target. The current implementation is
-// intentionally lightweight and only ensures the nuclei runner is invoked. It
-// returns an empty slice but can be extended to parse real results.
+// Scan runs nuclei against the provided target and returns the
+// vulnerabilities it discovered, ready to be persisted into the
+// vulnerabilities table.
 func (s *NucleiService) Scan(ctx context.Context, target string) ([]models.Vulnerability, error) {
-	// Referencing nucleiCore.Engine ensures the nuclei dependency is included.
-	_ = nucleiCore.Engine{}
-	return []models.Vulnerability{}, nil
+	var opts []nuclei.NucleiSDKOptions
+	if s.MinSeverity != "" {
+		opts = append(opts, nuclei.WithTemplateFilters(nuclei.TemplateFilters{Severity: s.MinSeverity}))
+	}
+	if s.TemplatesDir != "" {
+		opts = append(opts, nuclei.WithTemplatesOrWorkflows(nuclei.TemplateSources{Templates: []string{s.TemplatesDir}}))
+	}
+
+	engine, err := nuclei.NewNucleiEngine(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nuclei engine: %w", err)
+	}
+	defer engine.Close()
+
+	engine.LoadTargets([]string{target}, false)
+
+	now := time.Now()
+	var vulns []models.Vulnerability
+	err = engine.ExecuteWithCallback(func(event *output.ResultEvent) {
+		vulns = append(vulns, models.Vulnerability{
+			Target:       target,
+			Name:         event.Info.Name,
+			Description:  event.Info.Description,
+			Severity:     string(event.Info.SeverityHolder.Severity),
+			DiscoveredAt: now,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nuclei scan failed for %s: %w", target, err)
+	}
+
+	return vulns, nil
 }
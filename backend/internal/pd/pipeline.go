@@ -0,0 +1,111 @@
+package pd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reconya-ai/outputs"
+)
+
+// Pipeline chains the subfinder, httpx and nuclei services together. The
+// device scan orchestration calls RunForDevice once a device is found with
+// open HTTP/HTTPS ports, so results land against that device's row in
+// web_services and vulnerabilities. Dispatcher may be nil, in which case
+// results are persisted but not forwarded to any output sink.
+type Pipeline struct {
+	Subfinder  *SubfinderService
+	HTTPX      *HTTPXService
+	Nuclei     *NucleiService
+	Dispatcher *outputs.Dispatcher
+}
+
+// NewPipeline builds a Pipeline from its constituent services.
+func NewPipeline(subfinder *SubfinderService, httpx *HTTPXService, nuclei *NucleiService, dispatcher *outputs.Dispatcher) *Pipeline {
+	return &Pipeline{Subfinder: subfinder, HTTPX: httpx, Nuclei: nuclei, Dispatcher: dispatcher}
+}
+
+// RunForDevice enumerates subdomains of domain (when non-empty) and adds
+// them to hosts, probes the full host list with httpx, persists every
+// responsive service under deviceID in web_services, then runs nuclei
+// against each probed URL and persists findings under deviceID in
+// vulnerabilities.
+func (p *Pipeline) RunForDevice(ctx context.Context, db *sql.DB, deviceID, domain string, hosts []string) error {
+	if domain != "" {
+		discovered, err := p.Subfinder.Enumerate(ctx, domain)
+		if err != nil {
+			return fmt.Errorf("subfinder enumeration failed for %s: %w", domain, err)
+		}
+		hosts = append(hosts, discovered...)
+	}
+
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	probed, err := p.HTTPX.Probe(ctx, hosts)
+	if err != nil {
+		return fmt.Errorf("httpx probe failed: %w", err)
+	}
+
+	now := time.Now()
+	for _, result := range probed {
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO web_services (device_id, url, title, server, status_code, content_type, size, port, protocol, tls_version, scanned_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			deviceID, result.URL, result.Title, result.Server, result.StatusCode, result.ContentType, result.Size, result.Port, result.Protocol, result.TLSVersion, now,
+		); err != nil {
+			return fmt.Errorf("failed to persist web service %s: %w", result.URL, err)
+		}
+		p.emit(outputs.EventWebServiceDiscovered, outputs.SeverityInfo, deviceID, map[string]interface{}{
+			"url":         result.URL,
+			"status_code": result.StatusCode,
+		})
+
+		vulns, err := p.Nuclei.Scan(ctx, result.URL)
+		if err != nil {
+			return fmt.Errorf("nuclei scan failed for %s: %w", result.URL, err)
+		}
+
+		for _, vuln := range vulns {
+			id := vuln.ID
+			if id == "" {
+				id = uuid.NewString()
+			}
+			discoveredAt := vuln.DiscoveredAt
+			if discoveredAt.IsZero() {
+				discoveredAt = now
+			}
+
+			if _, err := db.ExecContext(ctx, `
+				INSERT INTO vulnerabilities (id, device_id, target, name, description, severity, discovered_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				id, deviceID, vuln.Target, vuln.Name, vuln.Description, vuln.Severity, discoveredAt,
+			); err != nil {
+				return fmt.Errorf("failed to persist vulnerability %s: %w", vuln.Name, err)
+			}
+			p.emit(outputs.EventVulnerabilityFound, outputs.Severity(vuln.Severity), deviceID, map[string]interface{}{
+				"name":   vuln.Name,
+				"target": vuln.Target,
+			})
+		}
+	}
+
+	return nil
+}
+
+// emit forwards event to the pipeline's dispatcher, if one is configured.
+func (p *Pipeline) emit(eventType outputs.EventType, severity outputs.Severity, deviceID string, data map[string]interface{}) {
+	if p.Dispatcher == nil {
+		return
+	}
+	p.Dispatcher.Emit(outputs.Event{
+		Type:     eventType,
+		Severity: severity,
+		DeviceID: deviceID,
+		Data:     data,
+	})
+}
@@ -1,23 +1,64 @@
 package pd
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"strings"
 
 	subRunner "github.com/projectdiscovery/subfinder/v2/pkg/runner"
 )
 
 // SubfinderService wraps the ProjectDiscovery subfinder runner.
-type SubfinderService struct{}
+type SubfinderService struct {
+	Threads            int
+	MaxEnumerationTime int // minutes
+}
 
-// NewSubfinderService returns a new instance.
+// NewSubfinderService returns a new instance with sane defaults.
 func NewSubfinderService() *SubfinderService {
-	return &SubfinderService{}
+	return &SubfinderService{
+		Threads:            10,
+		MaxEnumerationTime: 10,
+	}
 }
 
-// Enumerate discovers subdomains for the given domain. For now this function
-// simply references the subfinder runner to keep the dependency and returns an
-// empty slice.
+// Enumerate discovers subdomains for the given domain by running the
+// subfinder runner and returns the deduplicated list of hostnames it found.
 func (s *SubfinderService) Enumerate(ctx context.Context, domain string) ([]string, error) {
-	_ = subRunner.Options{}
-	return []string{}, nil
+	options := &subRunner.Options{
+		Threads:            s.Threads,
+		Timeout:            30,
+		MaxEnumerationTime: s.MaxEnumerationTime,
+		Silent:             true,
+		RemoveWildcard:     true,
+	}
+
+	runnerInstance, err := subRunner.NewRunner(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subfinder runner: %w", err)
+	}
+
+	var buf bytes.Buffer
+	found, err := runnerInstance.EnumerateSingleDomainWithCtx(ctx, domain, []io.Writer{&buf})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate subdomains for %s: %w", domain, err)
+	}
+
+	seen := make(map[string]struct{})
+	var hosts []string
+	for host := range found {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, nil
 }
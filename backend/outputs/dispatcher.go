@@ -0,0 +1,121 @@
+package outputs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultQueueSize bounds each sink's event queue so a slow output can't
+// stall the scanner; events are dropped (and counted) once it fills up.
+const defaultQueueSize = 256
+
+// sink pairs an Output with the filter that gates it and the bounded
+// channel events are queued on. dropped is incremented from Emit, which may
+// be called concurrently by multiple in-flight scans, so it's only ever
+// touched through sync/atomic.
+type sink struct {
+	output  Output
+	filter  Filter
+	queue   chan Event
+	dropped uint64
+}
+
+// Dispatcher stamps every event with an instance ID and fans it out to
+// every registered sink concurrently, each through its own bounded queue.
+// closeMu guards against Close racing with a concurrent Emit trying to
+// send on a queue Close has already closed: Emit holds the read lock
+// while it sends, Close takes the write lock before closing anything, so
+// Close can never run concurrently with a send.
+type Dispatcher struct {
+	instanceID string
+	sinks      []*sink
+	wg         sync.WaitGroup
+	closeMu    sync.RWMutex
+	closed     bool
+}
+
+// NewDispatcher creates a Dispatcher that stamps every event with
+// instanceID before handing it to registered outputs.
+func NewDispatcher(instanceID string) *Dispatcher {
+	return &Dispatcher{instanceID: instanceID}
+}
+
+// Register adds an output with its filter and starts its delivery worker.
+// queueSize <= 0 falls back to defaultQueueSize.
+func (d *Dispatcher) Register(ctx context.Context, output Output, filter Filter, queueSize int) {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	s := &sink{output: output, filter: filter, queue: make(chan Event, queueSize)}
+	d.sinks = append(d.sinks, s)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-s.queue:
+				if !ok {
+					return
+				}
+				if err := s.output.Emit(ctx, event); err != nil {
+					log.Printf("output %s failed to emit event: %v", s.output.Name(), err)
+				}
+			}
+		}
+	}()
+}
+
+// Emit stamps event with the instance ID and a timestamp (if unset) and
+// queues it on every registered sink whose filter allows it. A sink whose
+// queue is full drops the event rather than blocking the caller. Emit is a
+// no-op once Close has been called.
+func (d *Dispatcher) Emit(event Event) {
+	d.closeMu.RLock()
+	defer d.closeMu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	event.InstanceID = d.instanceID
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	for _, s := range d.sinks {
+		if !s.filter.Allow(event) {
+			continue
+		}
+		select {
+		case s.queue <- event:
+		default:
+			dropped := atomic.AddUint64(&s.dropped, 1)
+			log.Printf("output %s dropped event %s: queue full (%d dropped so far)", s.output.Name(), event.Type, dropped)
+		}
+	}
+}
+
+// Close stops Emit from accepting further events, closes every registered
+// sink's queue, waits for in-flight deliveries to finish, then closes the
+// outputs themselves.
+func (d *Dispatcher) Close() {
+	d.closeMu.Lock()
+	d.closed = true
+	for _, s := range d.sinks {
+		close(s.queue)
+	}
+	d.closeMu.Unlock()
+
+	d.wg.Wait()
+	for _, s := range d.sinks {
+		if err := s.output.Close(); err != nil {
+			log.Printf("output %s failed to close: %v", s.output.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package outputs
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// noopOutput discards every event; used to exercise the dispatcher without
+// a real destination.
+type noopOutput struct{}
+
+func (noopOutput) Name() string                            { return "noop" }
+func (noopOutput) Emit(ctx context.Context, _ Event) error { return nil }
+func (noopOutput) Close() error                            { return nil }
+
+func TestDispatcher_CloseDoesNotRaceWithEmit(t *testing.T) {
+	d := NewDispatcher("test-instance")
+	d.Register(context.Background(), noopOutput{}, Filter{}, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			d.Emit(Event{Type: EventDeviceDiscovered, Severity: SeverityInfo})
+		}
+	}()
+
+	d.Close()
+	wg.Wait()
+}
+
+func TestDispatcher_EmitAfterCloseIsNoop(t *testing.T) {
+	d := NewDispatcher("test-instance")
+	d.Register(context.Background(), noopOutput{}, Filter{}, 1)
+	d.Close()
+
+	// Must not panic with "send on closed channel".
+	d.Emit(Event{Type: EventDeviceDiscovered, Severity: SeverityInfo})
+}
@@ -0,0 +1,68 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ElasticsearchOutput indexes events into Elasticsearch using the bulk
+// API, one document per event.
+type ElasticsearchOutput struct {
+	URL        string
+	Index      string
+	httpClient *http.Client
+}
+
+// NewElasticsearchOutput returns an ElasticsearchOutput indexing into
+// index on the cluster reachable at url.
+func NewElasticsearchOutput(url, index string) *ElasticsearchOutput {
+	return &ElasticsearchOutput{
+		URL:        url,
+		Index:      index,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *ElasticsearchOutput) Name() string { return "elasticsearch" }
+
+func (e *ElasticsearchOutput) Emit(ctx context.Context, event Event) error {
+	action, err := json.Marshal(map[string]interface{}{
+		"index": map[string]string{"_index": e.Index},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk action: %w", err)
+	}
+	doc, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event for elasticsearch: %w", err)
+	}
+
+	var body bytes.Buffer
+	body.Write(action)
+	body.WriteByte('\n')
+	body.Write(doc)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL+"/_bulk", &body)
+	if err != nil {
+		return fmt.Errorf("failed to build elasticsearch request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event to elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("elasticsearch bulk index returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *ElasticsearchOutput) Close() error { return nil }
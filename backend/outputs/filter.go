@@ -0,0 +1,32 @@
+package outputs
+
+// Filter decides whether an event should be forwarded to a given output.
+// A zero-value Filter allows everything.
+type Filter struct {
+	MinSeverity Severity
+	EventTypes  []EventType // empty means every type passes
+}
+
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// Allow reports whether event passes the filter.
+func (f Filter) Allow(event Event) bool {
+	if f.MinSeverity != "" && severityRank[event.Severity] < severityRank[f.MinSeverity] {
+		return false
+	}
+	if len(f.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range f.EventTypes {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package outputs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaOutput publishes events as JSON messages to a Kafka topic.
+type KafkaOutput struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaOutput returns a KafkaOutput publishing to topic on the given
+// brokers.
+func NewKafkaOutput(brokers []string, topic string) *KafkaOutput {
+	return &KafkaOutput{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (k *KafkaOutput) Name() string { return "kafka" }
+
+func (k *KafkaOutput) Emit(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event for kafka: %w", err)
+	}
+
+	if err := k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.Type),
+		Value: body,
+	}); err != nil {
+		return fmt.Errorf("failed to publish event to kafka: %w", err)
+	}
+	return nil
+}
+
+func (k *KafkaOutput) Close() error {
+	return k.writer.Close()
+}
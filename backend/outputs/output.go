@@ -0,0 +1,54 @@
+// Package outputs implements pluggable sinks that forward scan events -
+// new devices, newly opened ports, vulnerabilities written to the
+// vulnerabilities table, and new web_services rows - to external systems
+// such as webhooks, syslog, Kafka or Elasticsearch.
+package outputs
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies what kind of scan event is being emitted.
+type EventType string
+
+const (
+	EventDeviceDiscovered     EventType = "device_discovered"
+	EventPortOpen             EventType = "port_open"
+	EventVulnerabilityFound   EventType = "vulnerability_found"
+	EventWebServiceDiscovered EventType = "web_service_discovered"
+)
+
+// Severity mirrors the scale used for vulnerabilities.severity; events
+// without a severity of their own (e.g. a new device) are emitted as
+// SeverityInfo.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Event is the payload handed to every configured Output.
+type Event struct {
+	Type       EventType              `json:"type"`
+	Severity   Severity               `json:"severity"`
+	Timestamp  time.Time              `json:"timestamp"`
+	InstanceID string                 `json:"instance_id"`
+	DeviceID   string                 `json:"device_id,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// Output is a destination that scan events can be forwarded to.
+type Output interface {
+	// Name identifies the output for logging and metrics.
+	Name() string
+	// Emit forwards a single event. Implementations should respect ctx
+	// cancellation rather than blocking indefinitely.
+	Emit(ctx context.Context, event Event) error
+	// Close releases any resources held by the output.
+	Close() error
+}
@@ -0,0 +1,67 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookOutput POSTs each event as JSON to a configured URL, signing the
+// body with HMAC-SHA256 so the receiver can verify it came from this
+// instance.
+type WebhookOutput struct {
+	URL        string
+	Secret     string
+	httpClient *http.Client
+}
+
+// NewWebhookOutput returns a WebhookOutput posting to url and signing
+// requests with secret.
+func NewWebhookOutput(url, secret string) *WebhookOutput {
+	return &WebhookOutput{
+		URL:        url,
+		Secret:     secret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *WebhookOutput) Name() string { return "webhook" }
+
+func (w *WebhookOutput) Emit(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event for webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Reconya-Signature", w.sign(body))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *WebhookOutput) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *WebhookOutput) Close() error { return nil }
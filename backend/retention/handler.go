@@ -0,0 +1,90 @@
+package retention
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes mounts the retention policy REST endpoints on router.
+func RegisterRoutes(router *mux.Router, store *Store) {
+	router.HandleFunc("/api/retention-policies", listPoliciesHandler(store)).Methods(http.MethodGet)
+	router.HandleFunc("/api/retention-policies", createPolicyHandler(store)).Methods(http.MethodPost)
+	router.HandleFunc("/api/retention-policies/{name}", updatePolicyHandler(store)).Methods(http.MethodPut)
+}
+
+type policyDTO struct {
+	Name            string `json:"name"`
+	TargetTable     string `json:"target_table"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	MaxRows         int    `json:"max_rows"`
+}
+
+func toDTO(p RetentionPolicy) policyDTO {
+	return policyDTO{
+		Name:            p.Name,
+		TargetTable:     p.TargetTable,
+		DurationSeconds: int64(p.Duration / time.Second),
+		MaxRows:         p.MaxRows,
+	}
+}
+
+func listPoliciesHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policies, err := store.List(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		dtos := make([]policyDTO, 0, len(policies))
+		for _, p := range policies {
+			dtos = append(dtos, toDTO(p))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dtos)
+	}
+}
+
+func createPolicyHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dto policyDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		upsertAndRespond(store, w, r, dto)
+	}
+}
+
+func updatePolicyHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dto policyDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		dto.Name = mux.Vars(r)["name"]
+		upsertAndRespond(store, w, r, dto)
+	}
+}
+
+func upsertAndRespond(store *Store, w http.ResponseWriter, r *http.Request, dto policyDTO) {
+	policy := RetentionPolicy{
+		Name:        dto.Name,
+		TargetTable: dto.TargetTable,
+		Duration:    time.Duration(dto.DurationSeconds) * time.Second,
+		MaxRows:     dto.MaxRows,
+	}
+
+	if err := store.Upsert(r.Context(), policy); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toDTO(policy))
+}
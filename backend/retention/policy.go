@@ -0,0 +1,40 @@
+// Package retention manages TTL-based expiry of scan artifacts (event
+// logs, vulnerabilities, web services, historical port scans and the
+// geolocation cache) so a long-running reconya install doesn't grow its
+// SQLite database without bound.
+package retention
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy describes how long rows in TargetTable are kept. The
+// background sweep deletes rows older than Duration; if MaxRows is greater
+// than zero it also trims the table down to that many rows, newest first.
+type RetentionPolicy struct {
+	Name        string
+	TargetTable string
+	Duration    time.Duration
+	MaxRows     int
+}
+
+// MarshalBinary encodes the policy so it can be exported, e.g. to back it
+// up or copy it to another reconya instance.
+func (p RetentionPolicy) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		return nil, fmt.Errorf("failed to marshal retention policy %s: %w", p.Name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a policy previously produced by MarshalBinary.
+func (p *RetentionPolicy) UnmarshalBinary(data []byte) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(p); err != nil {
+		return fmt.Errorf("failed to unmarshal retention policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Store persists retention policies in the retention_policies table.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore returns a Store backed by db. Callers should run
+// db.InitializeSchema (which creates retention_policies) before using it.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// List returns every configured retention policy.
+func (s *Store) List(ctx context.Context) ([]RetentionPolicy, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, target_table, duration_seconds, max_rows FROM retention_policies ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []RetentionPolicy
+	for rows.Next() {
+		var p RetentionPolicy
+		var seconds int64
+		if err := rows.Scan(&p.Name, &p.TargetTable, &seconds, &p.MaxRows); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		p.Duration = time.Duration(seconds) * time.Second
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// Upsert creates or updates the named policy.
+func (s *Store) Upsert(ctx context.Context, p RetentionPolicy) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO retention_policies (name, target_table, duration_seconds, max_rows, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			target_table = excluded.target_table,
+			duration_seconds = excluded.duration_seconds,
+			max_rows = excluded.max_rows,
+			updated_at = excluded.updated_at`,
+		p.Name, p.TargetTable, int64(p.Duration/time.Second), p.MaxRows, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert retention policy %s: %w", p.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// sweepBatchSize bounds how many rows a single DELETE removes, so a sweep
+// on a large table doesn't hold a long write lock under WAL.
+const sweepBatchSize = 500
+
+// timeColumns maps each retention-eligible table to the column used to
+// decide how old a row is.
+var timeColumns = map[string]string{
+	"event_logs":        "created_at",
+	"vulnerabilities":   "discovered_at",
+	"web_services":      "scanned_at",
+	"geolocation_cache": "expires_at",
+	"ports":             "scanned_at",
+}
+
+// StartWorker launches a goroutine that wakes on interval and sweeps every
+// configured retention policy. Call it once, right after
+// db.ConnectToSQLite; cancel ctx to stop the worker.
+func StartWorker(ctx context.Context, db *sql.DB, interval time.Duration) {
+	store := NewStore(db)
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := sweepOnce(ctx, db, store); err != nil {
+					log.Printf("retention sweep failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func sweepOnce(ctx context.Context, db *sql.DB, store *Store) error {
+	policies, err := store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load retention policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		deleted, err := sweepPolicy(ctx, db, policy)
+		if err != nil {
+			log.Printf("retention sweep of %s failed: %v", policy.Name, err)
+			continue
+		}
+		if deleted == 0 {
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO event_logs (type, description, created_at, updated_at)
+			VALUES (?, ?, ?, ?)`,
+			"retention_sweep",
+			fmt.Sprintf("retention policy %q deleted %d row(s) from %s", policy.Name, deleted, policy.TargetTable),
+			time.Now(), time.Now(),
+		); err != nil {
+			log.Printf("failed to record retention sweep event for %s: %v", policy.Name, err)
+		}
+	}
+	return nil
+}
+
+// sweepPolicy deletes rows older than policy.Duration, then trims down to
+// policy.MaxRows if it's set, both in sweepBatchSize chunks.
+func sweepPolicy(ctx context.Context, db *sql.DB, policy RetentionPolicy) (int64, error) {
+	timeColumn, ok := timeColumns[policy.TargetTable]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention target table %q", policy.TargetTable)
+	}
+
+	var total int64
+
+	if policy.Duration > 0 {
+		deleted, err := deleteBatches(ctx, db, fmt.Sprintf(`
+			DELETE FROM %s WHERE rowid IN (
+				SELECT rowid FROM %s WHERE %s < ? LIMIT ?
+			)`, policy.TargetTable, policy.TargetTable, timeColumn),
+			time.Now().Add(-policy.Duration),
+		)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired rows from %s: %w", policy.TargetTable, err)
+		}
+		total += deleted
+	}
+
+	if policy.MaxRows > 0 {
+		trimmed, err := trimToMaxRows(ctx, db, policy.TargetTable, timeColumn, policy.MaxRows)
+		if err != nil {
+			return total, fmt.Errorf("failed to trim %s to %d rows: %w", policy.TargetTable, policy.MaxRows, err)
+		}
+		total += trimmed
+	}
+
+	return total, nil
+}
+
+func deleteBatches(ctx context.Context, db *sql.DB, query string, arg interface{}) (int64, error) {
+	var total int64
+	for {
+		result, err := db.ExecContext(ctx, query, arg, sweepBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// trimToMaxRows deletes the oldest rows past maxRows, sweepBatchSize at a
+// time, keeping the newest maxRows rows ordered by timeColumn.
+func trimToMaxRows(ctx context.Context, db *sql.DB, table, timeColumn string, maxRows int) (int64, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s WHERE rowid IN (
+			SELECT rowid FROM %s ORDER BY %s DESC LIMIT ? OFFSET ?
+		)`, table, table, timeColumn)
+
+	var total int64
+	for {
+		result, err := db.ExecContext(ctx, query, sweepBatchSize, maxRows)
+		if err != nil {
+			return total, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"reconya-ai/db/migrations"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrations.Apply(db); err != nil {
+		t.Fatalf("failed to apply schema: %v", err)
+	}
+	return db
+}
+
+func insertEventLog(t *testing.T, db *sql.DB, age time.Duration) {
+	t.Helper()
+	createdAt := time.Now().Add(-age)
+	if _, err := db.Exec(`
+		INSERT INTO event_logs (type, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?)`,
+		"test", "test row", createdAt, createdAt,
+	); err != nil {
+		t.Fatalf("failed to insert event log: %v", err)
+	}
+}
+
+func countEventLogs(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM event_logs`).Scan(&count); err != nil {
+		t.Fatalf("failed to count event logs: %v", err)
+	}
+	return count
+}
+
+func TestSweepPolicy_DeletesRowsOlderThanDuration(t *testing.T) {
+	db := openTestDB(t)
+
+	insertEventLog(t, db, 2*time.Hour)
+	insertEventLog(t, db, 90*time.Minute)
+	insertEventLog(t, db, time.Minute)
+
+	policy := RetentionPolicy{Name: "logs", TargetTable: "event_logs", Duration: time.Hour}
+	deleted, err := sweepPolicy(context.Background(), db, policy)
+	if err != nil {
+		t.Fatalf("sweepPolicy() returned error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("sweepPolicy() deleted = %d, want 2", deleted)
+	}
+	if got := countEventLogs(t, db); got != 1 {
+		t.Errorf("event_logs has %d rows after sweep, want 1", got)
+	}
+}
+
+func TestSweepPolicy_BatchesAcrossMultiplePasses(t *testing.T) {
+	db := openTestDB(t)
+
+	rows := sweepBatchSize*2 + 3
+	for i := 0; i < rows; i++ {
+		insertEventLog(t, db, 2*time.Hour)
+	}
+
+	policy := RetentionPolicy{Name: "logs", TargetTable: "event_logs", Duration: time.Hour}
+	deleted, err := sweepPolicy(context.Background(), db, policy)
+	if err != nil {
+		t.Fatalf("sweepPolicy() returned error: %v", err)
+	}
+	if int(deleted) != rows {
+		t.Errorf("sweepPolicy() deleted = %d, want %d", deleted, rows)
+	}
+	if got := countEventLogs(t, db); got != 0 {
+		t.Errorf("event_logs has %d rows after sweep, want 0", got)
+	}
+}
+
+func TestSweepPolicy_TrimsToMaxRows(t *testing.T) {
+	db := openTestDB(t)
+
+	for i := 0; i < 5; i++ {
+		insertEventLog(t, db, time.Duration(i)*time.Minute)
+	}
+
+	policy := RetentionPolicy{Name: "logs", TargetTable: "event_logs", MaxRows: 3}
+	deleted, err := sweepPolicy(context.Background(), db, policy)
+	if err != nil {
+		t.Fatalf("sweepPolicy() returned error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("sweepPolicy() deleted = %d, want 2", deleted)
+	}
+	if got := countEventLogs(t, db); got != 3 {
+		t.Errorf("event_logs has %d rows after trim, want 3", got)
+	}
+}
+
+func TestSweepPolicy_UnknownTableReturnsError(t *testing.T) {
+	db := openTestDB(t)
+
+	policy := RetentionPolicy{Name: "bogus", TargetTable: "not_a_real_table", Duration: time.Hour}
+	if _, err := sweepPolicy(context.Background(), db, policy); err == nil {
+		t.Error("sweepPolicy() with an unknown target table returned nil error, want error")
+	}
+}